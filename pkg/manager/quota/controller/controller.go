@@ -25,9 +25,13 @@ import (
 
 	configv1alpha1 "github.com/kiosk-sh/kiosk/pkg/apis/config/v1alpha1"
 	"github.com/kiosk-sh/kiosk/pkg/constants"
+	"github.com/kiosk-sh/kiosk/pkg/quota/install"
+	"github.com/kiosk-sh/kiosk/pkg/quota/tracker"
 	"github.com/kiosk-sh/kiosk/pkg/util"
 
-	"k8s.io/klog"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
@@ -35,6 +39,8 @@ import (
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
@@ -42,6 +48,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/kubernetes/pkg/controller"
@@ -51,6 +58,19 @@ import (
 // NamespacedResourcesFunc knows how to discover namespaced resources.
 type NamespacedResourcesFunc func() ([]*metav1.APIResourceList, error)
 
+// registryConfiguration adapts an already-built quota.Registry to an install.QuotaConfiguration so
+// that options.Registry's evaluators can be merged with options.QuotaConfigurations.
+type registryConfiguration struct {
+	registry quota.Registry
+}
+
+func (r registryConfiguration) Evaluators() []quota.Evaluator {
+	if r.registry == nil {
+		return nil
+	}
+	return r.registry.List()
+}
+
 // AccountQuotaControllerOptions holds options for creating a quota controller
 type AccountQuotaControllerOptions struct {
 	// Manager is needed for kubernetes access and cache
@@ -63,6 +83,24 @@ type AccountQuotaControllerOptions struct {
 	IgnoredResourcesFunc func() map[schema.GroupResource]struct{}
 	// Controls full resync of objects monitored for replenishment.
 	ReplenishmentResyncPeriod controller.ResyncPeriodFunc
+	// DiscoveryFunc knows how to discover the namespaced resources the apiserver currently serves.
+	// It is used both to seed the initial set of quota monitors and to pick up resources (including
+	// CRDs) that are registered after the controller has started.
+	DiscoveryFunc NamespacedResourcesFunc
+	// InformerFactory constructs dynamic informers for arbitrary namespaced resources so that
+	// QuotaMonitor does not need a typed informer registered up front for every resource it tracks.
+	InformerFactory dynamicinformer.DynamicSharedInformerFactory
+	// QuotaConfigurations contribute additional evaluators to Registry, e.g. one per CRD-backed
+	// resource a downstream consumer of kiosk wants to track. See pkg/quota/install.
+	QuotaConfigurations []install.QuotaConfiguration
+	// ResourceAliases lets an AccountQuota reference a resource by a short, user-friendly name
+	// (e.g. "openshift.io/imagestreams") while the controller tracks the canonical
+	// count/<resource>.<group> name internally.
+	ResourceAliases map[v1.ResourceName]v1.ResourceName
+	// PendingUsage should be the same tracker passed to the admission webhook's
+	// NewAccountQuotaAdmission, so replenishQuota can recognize usage the webhook already applied to
+	// an AccountQuota's status and avoid adding it a second time once it observes the object itself.
+	PendingUsage *tracker.PendingUsage
 }
 
 // AccountQuotaController is responsible for tracking quota usage status in the system
@@ -76,11 +114,18 @@ type AccountQuotaController struct {
 	// missingUsageQueue holds objects that are missing the initial usage information
 	missingUsageQueue workqueue.RateLimitingInterface
 	// To allow injection of syncUsage for testing.
-	syncHandler func(key string) error
+	syncHandler func(ctx context.Context, key string) error
 	// function that controls full recalculation of quota usage
 	resyncPeriod controller.ResyncPeriodFunc
 	// knows how to calculate usage
 	registry quota.Registry
+	// knows how to discover the namespaced resources currently served by the apiserver
+	discoveryFunc NamespacedResourcesFunc
+	// maps a user-facing alias to the canonical resource name the controller tracks internally
+	resourceAliases map[v1.ResourceName]v1.ResourceName
+	// tracks usage the admission webhook already applied to an AccountQuota's status, so
+	// replenishment doesn't double-count it once it observes the object itself
+	pendingUsage *tracker.PendingUsage
 	// knows how to monitor all the resources tracked by quota and trigger replenishment
 	quotaMonitor *QuotaMonitor
 	// controls the workers that process quotas
@@ -91,6 +136,20 @@ type AccountQuotaController struct {
 
 // NewAccountQuotaController creates a quota controller with specified options
 func NewAccountQuotaController(options *AccountQuotaControllerOptions) (*AccountQuotaController, error) {
+	registerMetrics()
+
+	if rateLimiter := options.Manager.GetConfig().RateLimiter; rateLimiter != nil {
+		if err := RegisterMetricAndTrackRateLimiterUsage("account_quota_controller", rateLimiter); err != nil {
+			utilruntime.HandleError(fmt.Errorf("failed to register rate limiter metric: %v", err))
+		}
+	}
+
+	registry := options.Registry
+	if len(options.QuotaConfigurations) > 0 {
+		configurations := append([]install.QuotaConfiguration{registryConfiguration{registry}}, options.QuotaConfigurations...)
+		registry = install.NewRegistry(configurations...)
+	}
+
 	// build the account quota controller
 	rq := &AccountQuotaController{
 		manager:           options.Manager,
@@ -98,7 +157,10 @@ func NewAccountQuotaController(options *AccountQuotaControllerOptions) (*Account
 		queue:             workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "resourcequota_primary"),
 		missingUsageQueue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "resourcequota_priority"),
 		resyncPeriod:      options.ResyncPeriod,
-		registry:          options.Registry,
+		registry:          registry,
+		discoveryFunc:     options.DiscoveryFunc,
+		resourceAliases:   options.ResourceAliases,
+		pendingUsage:      options.PendingUsage,
 	}
 	// set the synchronization handler
 	rq.syncHandler = rq.syncResourceQuotaFromKey
@@ -163,6 +225,7 @@ func NewAccountQuotaController(options *AccountQuotaControllerOptions) (*Account
 
 	qm := &QuotaMonitor{
 		manager:           options.Manager,
+		informerFactory:   options.InformerFactory,
 		ignoredResources:  options.IgnoredResourcesFunc(),
 		resourceChanges:   workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "resource_quota_controller_resource_changes"),
 		resyncPeriod:      options.ReplenishmentResyncPeriod,
@@ -173,13 +236,19 @@ func NewAccountQuotaController(options *AccountQuotaControllerOptions) (*Account
 	rq.quotaMonitor = qm
 
 	// do initial quota monitor setup.  If we have a discovery failure here, it's ok. We'll discover more resources when a later sync happens.
-	resources, err := GetQuotableResources()
+	resources, err := GetQuotableResources(rq.discoveryFunc)
 	if discovery.IsGroupDiscoveryFailedError(err) {
 		utilruntime.HandleError(fmt.Errorf("initial discovery check failure, continuing and counting on future sync update: %v", err))
 	} else if err != nil {
 		return nil, err
 	}
 
+	// Anything discovery reports that isn't already natively covered by the registry (e.g. CRDs,
+	// or any other namespaced resource an operator wants to cap) gets a generic count/<resource>.<group>
+	// evaluator so AccountQuota.Spec.Quota.Hard can reference it without the controller knowing about
+	// the kind up front.
+	registerGenericEvaluators(rq.registry, qm.informerFactory, resources)
+
 	if err = qm.SyncMonitors(resources); err != nil {
 		utilruntime.HandleError(fmt.Errorf("initial monitor sync has error: %v", err))
 	}
@@ -212,10 +281,11 @@ func (rq *AccountQuotaController) enqueueNamespace(obj interface{}) {
 }
 
 // enqueueAll is called at the fullResyncPeriod interval to force a full recalculation of quota usage statistics
-func (rq *AccountQuotaController) enqueueAll() {
-	defer klog.V(4).Infof("Resource quota controller queued all resource quota for full calculation of usage")
+func (rq *AccountQuotaController) enqueueAll(ctx context.Context) {
+	logger := klog.FromContext(ctx)
+	defer logger.V(4).Info("Resource quota controller queued all resource quota for full calculation of usage")
 	accountQuotaList := &configv1alpha1.AccountQuotaList{}
-	err := rq.client.List(context.Background(), accountQuotaList)
+	err := rq.client.List(ctx, accountQuotaList)
 	if err != nil {
 		utilruntime.HandleError(fmt.Errorf("unable to enqueue all - error listing resource quotas: %v", err))
 		return
@@ -249,8 +319,10 @@ func (rq *AccountQuotaController) addQuota(obj interface{}) {
 
 	accountQuota := obj.(*configv1alpha1.AccountQuota)
 
-	// if we declared an intent that is not yet captured in status (prioritize it)
-	if !apiequality.Semantic.DeepEqual(accountQuota.Spec.Quota.Hard, accountQuota.Status.Total.Hard) {
+	// if we declared an intent that is not yet captured in status (prioritize it). Spec.Quota.Hard is
+	// normalized the same way syncResourceQuota normalizes it before comparing, so an AccountQuota
+	// that uses a configured alias doesn't permanently look dirty just because the key sets differ.
+	if !apiequality.Semantic.DeepEqual(rq.normalizeResourceNames(accountQuota.Spec.Quota.Hard), accountQuota.Status.Total.Hard) {
 		rq.missingUsageQueue.Add(key)
 		return
 	}
@@ -273,7 +345,7 @@ func (rq *AccountQuotaController) addQuota(obj interface{}) {
 }
 
 // worker runs a worker thread that just dequeues items, processes them, and marks them done.
-func (rq *AccountQuotaController) worker(queue workqueue.RateLimitingInterface) func() {
+func (rq *AccountQuotaController) worker(ctx context.Context, queue workqueue.RateLimitingInterface) func() {
 	workFunc := func() bool {
 		key, quit := queue.Get()
 		if quit {
@@ -282,7 +354,9 @@ func (rq *AccountQuotaController) worker(queue workqueue.RateLimitingInterface)
 		defer queue.Done(key)
 		rq.workerLock.RLock()
 		defer rq.workerLock.RUnlock()
-		err := rq.syncHandler(key.(string))
+
+		keyCtx := klog.NewContext(ctx, klog.FromContext(ctx).WithValues("accountQuota", key))
+		err := rq.syncHandler(keyCtx, key.(string))
 		if err == nil {
 			queue.Forget(key)
 			return false
@@ -295,7 +369,7 @@ func (rq *AccountQuotaController) worker(queue workqueue.RateLimitingInterface)
 	return func() {
 		for {
 			if quit := workFunc(); quit {
-				klog.Infof("resource quota controller worker shutting down")
+				klog.FromContext(ctx).Info("resource quota controller worker shutting down")
 				return
 			}
 		}
@@ -303,15 +377,16 @@ func (rq *AccountQuotaController) worker(queue workqueue.RateLimitingInterface)
 }
 
 // Run begins quota controller using the specified number of workers
-func (rq *AccountQuotaController) Run(workers int, stopCh <-chan struct{}) {
+func (rq *AccountQuotaController) Run(ctx context.Context, workers int) {
 	defer utilruntime.HandleCrash()
 	defer rq.queue.ShutDown()
 
-	klog.Infof("Starting resource quota controller")
-	defer klog.Infof("Shutting down resource quota controller")
+	logger := klog.FromContext(ctx)
+	logger.Info("Starting resource quota controller")
+	defer logger.Info("Shutting down resource quota controller")
 
 	if rq.quotaMonitor != nil {
-		go rq.quotaMonitor.Run(stopCh)
+		go rq.quotaMonitor.Run(ctx)
 	}
 
 	// This is not necessary since the underlying cache will take care of this
@@ -321,48 +396,60 @@ func (rq *AccountQuotaController) Run(workers int, stopCh <-chan struct{}) {
 
 	// the workers that chug through the quota calculation backlog
 	for i := 0; i < workers; i++ {
-		go wait.Until(rq.worker(rq.queue), time.Second, stopCh)
-		go wait.Until(rq.worker(rq.missingUsageQueue), time.Second, stopCh)
+		go wait.Until(rq.worker(ctx, rq.queue), time.Second, ctx.Done())
+		go wait.Until(rq.worker(ctx, rq.missingUsageQueue), time.Second, ctx.Done())
 	}
 	// the timer for how often we do a full recalculation across all quotas
-	go wait.Until(func() { rq.enqueueAll() }, rq.resyncPeriod(), stopCh)
-	<-stopCh
+	go wait.Until(func() { rq.enqueueAll(ctx) }, rq.resyncPeriod(), ctx.Done())
+	<-ctx.Done()
 }
 
 // syncResourceQuotaFromKey syncs a quota key
-func (rq *AccountQuotaController) syncResourceQuotaFromKey(key string) (err error) {
+func (rq *AccountQuotaController) syncResourceQuotaFromKey(ctx context.Context, key string) (err error) {
 	startTime := time.Now()
+	logger := klog.FromContext(ctx)
 	defer func() {
-		klog.V(4).Infof("Finished syncing resource quota %q (%v)", key, time.Since(startTime))
+		logger.V(4).Info("Finished syncing resource quota", "duration", time.Since(startTime))
 	}()
 
 	accountQuota := &configv1alpha1.AccountQuota{}
-	err = rq.client.Get(context.Background(), types.NamespacedName{Name: key}, accountQuota)
+	err = rq.client.Get(ctx, types.NamespacedName{Name: key}, accountQuota)
 	if errors.IsNotFound(err) {
-		klog.Infof("Resource quota has been deleted %v", key)
+		logger.Info("Resource quota has been deleted")
 		return nil
 	}
 	if err != nil {
-		klog.Infof("Unable to retrieve resource quota %v from store: %v", key, err)
+		logger.Info("Unable to retrieve resource quota from store", "err", err)
 		return err
 	}
-	return rq.syncResourceQuota(accountQuota)
+
+	ctx = klog.NewContext(ctx, logger.WithValues("account", accountQuota.Spec.Account))
+	return rq.syncResourceQuota(ctx, accountQuota)
 }
 
 // syncResourceQuota runs a complete sync of resource quota status across all known kinds
-func (rq *AccountQuotaController) syncResourceQuota(accountQuota *configv1alpha1.AccountQuota) (err error) {
+func (rq *AccountQuotaController) syncResourceQuota(ctx context.Context, accountQuota *configv1alpha1.AccountQuota) (err error) {
+	logger := klog.FromContext(ctx)
+	reconcileTimer := prometheus.NewTimer(reconcileDurationSeconds.WithLabelValues(accountQuota.Spec.Account))
+	defer reconcileTimer.ObserveDuration()
+
+	// resolve any user-facing alias (e.g. "openshift.io/imagestreams") to the canonical
+	// count/<resource>.<group> name the controller tracks internally, so the rest of the sync never
+	// has to special-case aliases.
+	specHard := rq.normalizeResourceNames(accountQuota.Spec.Quota.Hard)
+
 	// quota is dirty if any part of spec hard limits differs from the status hard limits
-	statusLimitsDirty := !apiequality.Semantic.DeepEqual(accountQuota.Spec.Quota.Hard, accountQuota.Status.Total.Hard)
+	statusLimitsDirty := !apiequality.Semantic.DeepEqual(specHard, accountQuota.Status.Total.Hard)
 
 	// dirty tracks if the usage status differs from the previous sync,
 	// if so, we send a new usage with latest status
 	// if this is our first sync, it will be dirty by default, since we need track usage
 	dirty := statusLimitsDirty || accountQuota.Status.Total.Hard == nil || accountQuota.Status.Total.Used == nil
-	hardLimits := quota.Add(v1.ResourceList{}, accountQuota.Spec.Quota.Hard)
+	hardLimits := quota.Add(v1.ResourceList{}, specHard)
 
 	// iterate over all quota namespaces and calculate usages
 	namespaceList := &v1.NamespaceList{}
-	err = rq.client.List(context.Background(), namespaceList, client.MatchingField(constants.IndexByAccount, accountQuota.Spec.Account))
+	err = rq.client.List(ctx, namespaceList, client.MatchingField(constants.IndexByAccount, accountQuota.Spec.Account))
 	if err != nil {
 		return err
 	}
@@ -414,9 +501,12 @@ func (rq *AccountQuotaController) syncResourceQuota(accountQuota *configv1alpha1
 
 	dirty = dirty || !quota.Equals(usage.Status.Total.Used, accountQuota.Status.Total.Used)
 
+	observeQuotaStatus(accountQuota.Spec.Account, usage.Status.Total)
+
 	// there was a change observed by this controller that requires we update quota
 	if dirty {
-		err = rq.client.Status().Update(context.Background(), usage)
+		logger.V(4).Info("Updating resource quota status")
+		err = rq.client.Status().Update(ctx, usage)
 		if err != nil {
 			errors = append(errors, err)
 		}
@@ -424,17 +514,53 @@ func (rq *AccountQuotaController) syncResourceQuota(accountQuota *configv1alpha1
 	return utilerrors.NewAggregate(errors)
 }
 
-// replenishQuota is a replenishment function invoked by a controller to notify that a quota should be recalculated
-func (rq *AccountQuotaController) replenishQuota(groupResource schema.GroupResource, namespace string) {
+// normalizeResourceNames rewrites any key in resources that has a configured alias to its canonical
+// count/<resource>.<group> form, leaving everything else untouched.
+func (rq *AccountQuotaController) normalizeResourceNames(resources v1.ResourceList) v1.ResourceList {
+	if len(rq.resourceAliases) == 0 {
+		return resources
+	}
+
+	normalized := make(v1.ResourceList, len(resources))
+	for name, quantity := range resources {
+		if canonical, ok := rq.resourceAliases[name]; ok {
+			name = canonical
+		}
+		normalized[name] = quantity
+	}
+	return normalized
+}
+
+// replenishQuota is a replenishment function invoked by a controller to notify that a quota should be
+// recalculated because object (or oldObject, on delete) of groupResource changed in namespace. Where
+// possible it patches only the affected namespace's usage via syncNamespaceDelta; it falls back to a
+// full recalculation of the quota when the targeted patch can't be computed.
+func (rq *AccountQuotaController) replenishQuota(ctx context.Context, groupResource schema.GroupResource, namespace string, object, oldObject runtime.Object) {
+	logger := klog.FromContext(ctx).WithValues("groupResource", groupResource, "namespace", namespace)
+
 	// check if the quota controller can evaluate this groupResource, if not, ignore it altogether...
 	evaluator := rq.registry.Get(groupResource)
 	if evaluator == nil {
 		return
 	}
 
+	ns := &v1.Namespace{}
+	if err := rq.client.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		if errors.IsNotFound(err) {
+			return
+		}
+		utilruntime.HandleError(fmt.Errorf("error looking up namespace %s for replenishment: %v", namespace, err))
+		return
+	}
+	account := util.GetAccountFromNamespace(ns)
+	if account == "" {
+		return
+	}
+	logger = logger.WithValues("account", account)
+
 	// check if this namespace even has a quota...
 	accountQuotaList := &configv1alpha1.AccountQuotaList{}
-	err := rq.client.List(context.Background(), accountQuotaList)
+	err := rq.client.List(ctx, accountQuotaList, client.MatchingField(constants.IndexByAccount, account))
 	if errors.IsNotFound(err) {
 		utilruntime.HandleError(fmt.Errorf("quota controller could not find ResourceQuota associated with namespace: %s, could take up to %v before a quota replenishes", namespace, rq.resyncPeriod()))
 		return
@@ -447,24 +573,135 @@ func (rq *AccountQuotaController) replenishQuota(groupResource schema.GroupResou
 		return
 	}
 
-	// only queue those quotas that are tracking a resource associated with this kind.
+	// Events off the dynamic informer always carry *unstructured.Unstructured. Native evaluators
+	// (e.g. the core pod evaluator) type-switch on the concrete Go type and error on anything else,
+	// so convert to the type the scheme knows for this kind before asking the evaluator for usage.
+	// Resources with no registered Go type (CRDs handled by the generic object-count evaluator) are
+	// left as unstructured - that evaluator doesn't care about the concrete type.
+	object = rq.toEvaluatorObject(object)
+	oldObject = rq.toEvaluatorObject(oldObject)
+
+	// Admission applies an admitted create's usage to every quota of the account that constrains it
+	// but marks its UID only once, and syncNamespaceDelta clears that mark. Re-mark it before each
+	// quota below so none of them adds the usage a second time.
+	var appliedUID types.UID
+	if oldObject == nil && rq.pendingUsage != nil {
+		if metaObj, ok := object.(metav1.Object); ok && rq.pendingUsage.TakeIfApplied(metaObj.GetUID()) {
+			appliedUID = metaObj.GetUID()
+		}
+	}
+
+	// only touch those quotas that are tracking a resource associated with this kind.
 	for i := range accountQuotaList.Items {
 		accountQuota := &accountQuotaList.Items[i]
 		accountQuotaResources := quota.ResourceNames(accountQuota.Status.Total.Hard)
 		if intersection := evaluator.MatchingResources(accountQuotaResources); len(intersection) > 0 {
-			// TODO: make this support targeted replenishment to a specific kind, right now it does a full recalc on that quota.
-			rq.enqueueAccountQuota(accountQuota)
+			if appliedUID != "" {
+				rq.pendingUsage.MarkApplied(appliedUID)
+			}
+			if err := rq.syncNamespaceDelta(ctx, accountQuota, namespace, evaluator, object, oldObject); err != nil {
+				logger.WithValues("accountQuota", accountQuota.Name).Info("targeted replenishment failed, falling back to full recalculation", "err", err)
+				rq.enqueueAccountQuota(accountQuota)
+			}
+		}
+	}
+}
+
+// toEvaluatorObject converts an *unstructured.Unstructured object to the concrete Go type the
+// manager's scheme registers for its GroupVersionKind, if any. object is returned unconverted if it
+// isn't unstructured, if the scheme has no Go type for its kind, or if the conversion fails.
+func (rq *AccountQuotaController) toEvaluatorObject(object runtime.Object) runtime.Object {
+	u, ok := object.(*unstructured.Unstructured)
+	if !ok {
+		return object
+	}
+
+	gvk := u.GroupVersionKind()
+	typed, err := rq.manager.GetScheme().New(gvk)
+	if err != nil {
+		return object
+	}
+
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), typed); err != nil {
+		utilruntime.HandleError(fmt.Errorf("converting %v to %T for quota evaluation: %v", gvk, typed, err))
+		return object
+	}
+	return typed
+}
+
+// syncNamespaceDelta patches the used quantities for a single namespace entry of accountQuota by
+// applying the delta between oldObject's and object's usage (object is nil on delete, oldObject is
+// nil on create), without re-listing or re-counting any other namespace or resource the account
+// tracks. It returns an error - causing the caller to fall back to a full recalculation - if the
+// object's usage can't be computed or the namespace has no prior recorded usage to patch.
+func (rq *AccountQuotaController) syncNamespaceDelta(ctx context.Context, accountQuota *configv1alpha1.AccountQuota, namespace string, evaluator quota.Evaluator, object, oldObject runtime.Object) error {
+	hardResources := quota.ResourceNames(accountQuota.Status.Total.Hard)
+
+	delta := v1.ResourceList{}
+	if object != nil {
+		// A create the admission webhook already admitted has its usage applied to accountQuota's
+		// status at admission time. Applying it again here, once the informer observes the object
+		// itself, would double-count it, so skip the object's own contribution in that case.
+		alreadyApplied := false
+		if oldObject == nil && rq.pendingUsage != nil {
+			if metaObj, ok := object.(metav1.Object); ok {
+				alreadyApplied = rq.pendingUsage.TakeIfApplied(metaObj.GetUID())
+			}
+		}
+
+		if !alreadyApplied {
+			usage, err := evaluator.Usage(object)
+			if err != nil {
+				return fmt.Errorf("computing usage for new object: %v", err)
+			}
+			delta = quota.Add(delta, quota.Mask(usage, hardResources))
+		}
+	}
+	if oldObject != nil {
+		usage, err := evaluator.Usage(oldObject)
+		if err != nil {
+			return fmt.Errorf("computing usage for old object: %v", err)
+		}
+		delta = quota.Subtract(delta, quota.Mask(usage, hardResources))
+	}
+	if len(delta) == 0 {
+		return nil
+	}
+
+	nsIndex := -1
+	for i := range accountQuota.Status.Namespaces {
+		if accountQuota.Status.Namespaces[i].Namespace == namespace {
+			nsIndex = i
+			break
 		}
 	}
+	if nsIndex == -1 {
+		return fmt.Errorf("no prior usage recorded for namespace %s", namespace)
+	}
+
+	updated := accountQuota.DeepCopy()
+
+	nsUsed := quota.Add(v1.ResourceList{}, updated.Status.Namespaces[nsIndex].Status.Used)
+	nsUsed = quota.Mask(quota.Add(nsUsed, delta), hardResources)
+	updated.Status.Namespaces[nsIndex].Status.Used = nsUsed
+
+	totalUsed := quota.Add(v1.ResourceList{}, updated.Status.Total.Used)
+	totalUsed = quota.Mask(quota.Add(totalUsed, delta), hardResources)
+	updated.Status.Total.Used = totalUsed
+
+	return rq.client.Status().Update(ctx, updated)
 }
 
 // Sync periodically resyncs the controller when new resources are observed from discovery.
-func (rq *AccountQuotaController) Sync(discoveryFunc NamespacedResourcesFunc, period time.Duration, stopCh <-chan struct{}) {
+func (rq *AccountQuotaController) Sync(ctx context.Context, discoveryFunc NamespacedResourcesFunc, period time.Duration) {
+	logger := klog.FromContext(ctx)
+	stopCh := ctx.Done()
+
 	// Something has changed, so track the new state and perform a sync.
 	oldResources := make(map[schema.GroupVersionResource]struct{})
 	wait.Until(func() {
 		// Get the current resource list from discovery.
-		newResources, err := GetQuotableResources()
+		newResources, err := GetQuotableResources(discoveryFunc)
 		if err != nil {
 			utilruntime.HandleError(err)
 
@@ -481,7 +718,7 @@ func (rq *AccountQuotaController) Sync(discoveryFunc NamespacedResourcesFunc, pe
 
 		// Decide whether discovery has reported a change.
 		if reflect.DeepEqual(oldResources, newResources) {
-			klog.V(4).Infof("no resource updates from discovery, skipping resource quota sync")
+			logger.V(4).Info("no resource updates from discovery, skipping resource quota sync")
 			return
 		}
 
@@ -491,9 +728,11 @@ func (rq *AccountQuotaController) Sync(discoveryFunc NamespacedResourcesFunc, pe
 		defer rq.workerLock.Unlock()
 
 		// Something has changed, so track the new state and perform a sync.
-		if klog.V(2) {
-			klog.Infof("syncing resource quota controller with updated resources from discovery: %s", printDiff(oldResources, newResources))
-		}
+		logger.V(2).Info("syncing resource quota controller with updated resources from discovery", "diff", printDiff(oldResources, newResources))
+
+		// Any newly discovered resource that the registry doesn't natively evaluate gets a generic
+		// object-count evaluator registered before the monitors for it are started.
+		registerGenericEvaluators(rq.registry, rq.quotaMonitor.informerFactory, newResources)
 
 		// Perform the monitor resync and wait for controllers to report cache sync.
 		if err := rq.resyncMonitors(newResources); err != nil {
@@ -511,7 +750,7 @@ func (rq *AccountQuotaController) Sync(discoveryFunc NamespacedResourcesFunc, pe
 
 		// success, remember newly synced resources
 		oldResources = newResources
-		klog.V(2).Infof("synced quota controller")
+		logger.V(2).Info("synced quota controller")
 	}, period, stopCh)
 }
 
@@ -559,24 +798,19 @@ func (rq *AccountQuotaController) resyncMonitors(resources map[schema.GroupVersi
 }
 
 // GetQuotableResources returns all resources that the quota system should recognize.
-// It requires a resource supports the following verbs: 'create','list','delete'
+// It requires a resource supports the following verbs: 'create','list','watch','delete'
 // This function may return both results and an error.  If that happens, it means that the discovery calls were only
 // partially successful.  A decision about whether to proceed or not is left to the caller.
-func GetQuotableResources() (map[schema.GroupVersionResource]struct{}, error) {
-	/*possibleResources, discoveryErr := discoveryFunc()
+func GetQuotableResources(discoveryFunc NamespacedResourcesFunc) (map[schema.GroupVersionResource]struct{}, error) {
+	possibleResources, discoveryErr := discoveryFunc()
 	if discoveryErr != nil && len(possibleResources) == 0 {
 		return nil, fmt.Errorf("failed to discover resources: %v", discoveryErr)
 	}
 	quotableResources := discovery.FilteredBy(discovery.SupportsAllVerbs{Verbs: []string{"create", "list", "watch", "delete"}}, possibleResources)
 	quotableGroupVersionResources, err := discovery.GroupVersionResources(quotableResources)
 	if err != nil {
-		return nil, fmt.Errorf("Failed to parse resources: %v", err)
+		return nil, fmt.Errorf("failed to parse resources: %v", err)
 	}
 	// return the original discovery error (if any) in addition to the list
-	return quotableGroupVersionResources, discoveryErr*/
-
-	// We only do pods for now
-	return map[schema.GroupVersionResource]struct{}{
-		schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}: struct{}{},
-	}, nil
+	return quotableGroupVersionResources, discoveryErr
 }
@@ -0,0 +1,157 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	configv1alpha1 "github.com/kiosk-sh/kiosk/pkg/apis/config/v1alpha1"
+	"github.com/kiosk-sh/kiosk/pkg/quota/tracker"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	quota "k8s.io/kubernetes/pkg/quota/v1"
+	"k8s.io/kubernetes/pkg/quota/v1/generic"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// podCountEvaluator is a generic object-count evaluator over a fixed GroupResource, standing in for a
+// real native evaluator so these tests don't depend on any informer machinery.
+func podCountEvaluator() quota.Evaluator {
+	listFunc := func(namespace string) ([]runtime.Object, error) { return nil, nil }
+	return generic.NewObjectCountEvaluator(schema.GroupResource{Resource: "pods"}, listFunc, "count/pods")
+}
+
+func newTestAccountQuota(namespace string) *configv1alpha1.AccountQuota {
+	return &configv1alpha1.AccountQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-quota"},
+		Spec: configv1alpha1.AccountQuotaSpec{
+			Account: "test-account",
+			Quota: v1.ResourceQuotaSpec{
+				Hard: v1.ResourceList{"count/pods": resource.MustParse("10")},
+			},
+		},
+		Status: configv1alpha1.AccountQuotaStatus{
+			Total: v1.ResourceQuotaStatus{
+				Hard: v1.ResourceList{"count/pods": resource.MustParse("10")},
+				Used: v1.ResourceList{"count/pods": resource.MustParse("2")},
+			},
+			Namespaces: configv1alpha1.AccountQuotasStatusByNamespace{
+				{
+					Namespace: namespace,
+					Status: v1.ResourceQuotaStatus{
+						Used: v1.ResourceList{"count/pods": resource.MustParse("2")},
+					},
+				},
+			},
+		},
+	}
+}
+
+func newTestController(t *testing.T, initialObjects ...runtime.Object) (*AccountQuotaController, *configv1alpha1.AccountQuota) {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("adding client-go types to scheme: %v", err)
+	}
+	if err := configv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("adding config/v1alpha1 types to scheme: %v", err)
+	}
+
+	accountQuota := newTestAccountQuota("test-namespace")
+	objects := append([]runtime.Object{accountQuota}, initialObjects...)
+
+	fakeClient := fake.NewFakeClientWithScheme(scheme, objects...)
+
+	rq := &AccountQuotaController{
+		client:       fakeClient,
+		pendingUsage: tracker.New(),
+	}
+
+	current := &configv1alpha1.AccountQuota{}
+	if err := fakeClient.Get(context.Background(), types.NamespacedName{Name: accountQuota.Name}, current); err != nil {
+		t.Fatalf("fetching seeded account quota: %v", err)
+	}
+	return rq, current
+}
+
+func TestSyncNamespaceDeltaAddsUsageForNewObject(t *testing.T) {
+	rq, accountQuota := newTestController(t)
+	evaluator := podCountEvaluator()
+
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod", UID: types.UID("pod-uid")}}
+
+	if err := rq.syncNamespaceDelta(context.Background(), accountQuota, "test-namespace", evaluator, pod, nil); err != nil {
+		t.Fatalf("syncNamespaceDelta returned an error: %v", err)
+	}
+
+	updated := &configv1alpha1.AccountQuota{}
+	if err := rq.client.Get(context.Background(), types.NamespacedName{Name: accountQuota.Name}, updated); err != nil {
+		t.Fatalf("fetching updated account quota: %v", err)
+	}
+
+	if got := updated.Status.Total.Used["count/pods"]; got.String() != "3" {
+		t.Errorf("Status.Total.Used[count/pods] = %s, want 3", got.String())
+	}
+	if got := updated.Status.Namespaces[0].Status.Used["count/pods"]; got.String() != "3" {
+		t.Errorf("Status.Namespaces[0].Status.Used[count/pods] = %s, want 3", got.String())
+	}
+}
+
+func TestSyncNamespaceDeltaSkipsUsageAlreadyAppliedByAdmission(t *testing.T) {
+	rq, accountQuota := newTestController(t)
+	evaluator := podCountEvaluator()
+
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod", UID: types.UID("pod-uid")}}
+	rq.pendingUsage.MarkApplied(pod.UID)
+
+	if err := rq.syncNamespaceDelta(context.Background(), accountQuota, "test-namespace", evaluator, pod, nil); err != nil {
+		t.Fatalf("syncNamespaceDelta returned an error: %v", err)
+	}
+
+	updated := &configv1alpha1.AccountQuota{}
+	if err := rq.client.Get(context.Background(), types.NamespacedName{Name: accountQuota.Name}, updated); err != nil {
+		t.Fatalf("fetching updated account quota: %v", err)
+	}
+
+	// usage was already applied by admission, so the informer-driven create event must not add it again
+	if got := updated.Status.Total.Used["count/pods"]; got.String() != "2" {
+		t.Errorf("Status.Total.Used[count/pods] = %s, want unchanged at 2", got.String())
+	}
+}
+
+func TestSyncNamespaceDeltaSubtractsUsageForDeletedObject(t *testing.T) {
+	rq, accountQuota := newTestController(t)
+	evaluator := podCountEvaluator()
+
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod", UID: types.UID("pod-uid")}}
+
+	if err := rq.syncNamespaceDelta(context.Background(), accountQuota, "test-namespace", evaluator, nil, pod); err != nil {
+		t.Fatalf("syncNamespaceDelta returned an error: %v", err)
+	}
+
+	updated := &configv1alpha1.AccountQuota{}
+	if err := rq.client.Get(context.Background(), types.NamespacedName{Name: accountQuota.Name}, updated); err != nil {
+		t.Fatalf("fetching updated account quota: %v", err)
+	}
+
+	if got := updated.Status.Total.Used["count/pods"]; got.String() != "1" {
+		t.Errorf("Status.Total.Used[count/pods] = %s, want 1", got.String())
+	}
+}
+
+func TestSyncNamespaceDeltaErrorsWithoutPriorNamespaceUsage(t *testing.T) {
+	rq, accountQuota := newTestController(t)
+	evaluator := podCountEvaluator()
+
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod", UID: types.UID("pod-uid")}}
+
+	if err := rq.syncNamespaceDelta(context.Background(), accountQuota, "other-namespace", evaluator, pod, nil); err == nil {
+		t.Error("expected an error for a namespace with no prior recorded usage, got nil")
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2014 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	quota "k8s.io/kubernetes/pkg/quota/v1"
+	"k8s.io/kubernetes/pkg/quota/v1/generic"
+)
+
+// genericResourceName builds the count/<resource>.<group> name that AccountQuota.Spec.Quota.Hard
+// (and Status.Total) use to reference a resource that has no native evaluator, mirroring upstream's
+// convention for counting arbitrary objects (e.g. CRDs).
+func genericResourceName(gr schema.GroupResource) v1.ResourceName {
+	if gr.Group == "" {
+		return v1.ResourceName(fmt.Sprintf("count/%s", gr.Resource))
+	}
+	return v1.ResourceName(fmt.Sprintf("count/%s.%s", gr.Resource, gr.Group))
+}
+
+// registerGenericEvaluators adds a generic object-count evaluator to registry for every resource in
+// resources that isn't already natively evaluated (e.g. pods), so that AccountQuota limits such as
+// `count/deployments.apps: "10"` are enforceable without a typed evaluator for that kind.
+func registerGenericEvaluators(registry quota.Registry, informerFactory dynamicinformer.DynamicSharedInformerFactory, resources map[schema.GroupVersionResource]struct{}) {
+	if informerFactory == nil {
+		return
+	}
+
+	for gvr := range resources {
+		groupResource := schema.GroupResource{Group: gvr.Group, Resource: gvr.Resource}
+		if registry.Get(groupResource) != nil {
+			continue
+		}
+
+		evaluator := generic.NewObjectCountEvaluator(groupResource, listFuncByNamespace(informerFactory, gvr), genericResourceName(groupResource))
+		registry.Add(evaluator)
+	}
+}
+
+// listFuncByNamespace adapts a dynamic informer's namespaced lister to generic.ListFuncByNamespace.
+func listFuncByNamespace(informerFactory dynamicinformer.DynamicSharedInformerFactory, gvr schema.GroupVersionResource) generic.ListFuncByNamespace {
+	lister := informerFactory.ForResource(gvr).Lister()
+
+	return func(namespace string) ([]runtime.Object, error) {
+		objs, err := lister.ByNamespace(namespace).List(labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+		return objs, nil
+	}
+}
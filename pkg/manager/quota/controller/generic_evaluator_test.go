@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestGenericResourceName(t *testing.T) {
+	tests := []struct {
+		name string
+		gr   schema.GroupResource
+		want string
+	}{
+		{
+			name: "core group has no dot-suffix",
+			gr:   schema.GroupResource{Resource: "configmaps"},
+			want: "count/configmaps",
+		},
+		{
+			name: "non-core group is suffixed with the group",
+			gr:   schema.GroupResource{Group: "apps", Resource: "deployments"},
+			want: "count/deployments.apps",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := genericResourceName(tt.gr); string(got) != tt.want {
+				t.Errorf("genericResourceName(%+v) = %q, want %q", tt.gr, got, tt.want)
+			}
+		})
+	}
+}
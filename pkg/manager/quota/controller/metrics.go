@@ -0,0 +1,155 @@
+package controller
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/component-base/metrics/prometheus/ratelimiter"
+
+	quota "k8s.io/kubernetes/pkg/quota/v1"
+)
+
+const (
+	metricsNamespace = "kiosk"
+	metricsSubsystem = "account_quota_controller"
+)
+
+var (
+	reconcileDurationSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "reconcile_duration_seconds",
+		Help:      "Time it took to reconcile an AccountQuota's usage, by account",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"account"})
+
+	hardLimitViolationsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "hard_limit_violations_total",
+		Help:      "Number of reconciles that observed usage exceeding an AccountQuota's hard limit, by account and resource",
+	}, []string{"account", "resource"})
+
+	usedQuantity = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "used",
+		Help:      "Status.Total.Used, by account and resource",
+	}, []string{"account", "resource"})
+
+	hardQuantity = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "hard",
+		Help:      "Status.Total.Hard, by account and resource",
+	}, []string{"account", "resource"})
+)
+
+var registerMetricsOnce sync.Once
+
+// registerMetrics registers the controller's own collectors and installs a Prometheus-backed
+// workqueue.MetricsProvider, so both queue and missingUsageQueue get depth and other workqueue
+// collectors without either workqueue.NewNamedRateLimitingQueue call site needing to know about it.
+// The provider only creates those per-queue collectors; it does not register them with a Prometheus
+// registry, so they are not exported.
+// It is safe to call more than once (e.g. if multiple controllers are constructed in a process).
+func registerMetrics() {
+	registerMetricsOnce.Do(func() {
+		prometheus.MustRegister(reconcileDurationSeconds, hardLimitViolationsTotal, usedQuantity, hardQuantity)
+		workqueue.SetProvider(prometheusMetricsProvider{})
+	})
+}
+
+// observeQuotaStatus records the used/hard gauges for every resource status tracks for account, and
+// counts any resource whose usage exceeds its hard limit.
+func observeQuotaStatus(account string, status v1.ResourceQuotaStatus) {
+	for name, q := range status.Used {
+		usedQuantity.WithLabelValues(account, string(name)).Set(q.AsApproximateFloat64())
+	}
+	for name, q := range status.Hard {
+		hardQuantity.WithLabelValues(account, string(name)).Set(q.AsApproximateFloat64())
+	}
+	for _, violated := range quota.IsNegative(quota.Subtract(status.Hard, status.Used)) {
+		hardLimitViolationsTotal.WithLabelValues(account, string(violated)).Inc()
+	}
+}
+
+// RegisterMetricAndTrackRateLimiterUsage exposes rateLimiter's usage as a gauge for ownerName,
+// matching the metric naming upstream's resource_quota_controller uses for the client-go rate
+// limiter backing its client, so operators can reuse existing dashboards and alerts.
+func RegisterMetricAndTrackRateLimiterUsage(ownerName string, rateLimiter flowcontrol.RateLimiter) error {
+	return ratelimiter.RegisterMetricAndTrackRateLimiterUsage(ownerName, rateLimiter)
+}
+
+// prometheusMetricsProvider implements k8s.io/client-go/util/workqueue.MetricsProvider, naming
+// collectors the same way upstream's resource_quota_controller does for its queue metrics.
+type prometheusMetricsProvider struct{}
+
+func (prometheusMetricsProvider) NewDepthMetric(name string) workqueue.GaugeMetric {
+	return prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "workqueue",
+		Name:      name + "_depth",
+		Help:      "Current depth of workqueue " + name,
+	})
+}
+
+func (prometheusMetricsProvider) NewAddsMetric(name string) workqueue.CounterMetric {
+	return prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "workqueue",
+		Name:      name + "_adds_total",
+		Help:      "Total number of adds handled by workqueue " + name,
+	})
+}
+
+func (prometheusMetricsProvider) NewLatencyMetric(name string) workqueue.HistogramMetric {
+	return prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "workqueue",
+		Name:      name + "_queue_duration_seconds",
+		Help:      "How long an item stays in workqueue " + name + " before being requested",
+		Buckets:   prometheus.DefBuckets,
+	})
+}
+
+func (prometheusMetricsProvider) NewWorkDurationMetric(name string) workqueue.HistogramMetric {
+	return prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "workqueue",
+		Name:      name + "_work_duration_seconds",
+		Help:      "How long processing an item from workqueue " + name + " takes",
+		Buckets:   prometheus.DefBuckets,
+	})
+}
+
+func (prometheusMetricsProvider) NewUnfinishedWorkSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "workqueue",
+		Name:      name + "_unfinished_work_seconds",
+		Help:      "How long in-progress items from workqueue " + name + " have been processing",
+	})
+}
+
+func (prometheusMetricsProvider) NewLongestRunningProcessorSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "workqueue",
+		Name:      name + "_longest_running_processor_seconds",
+		Help:      "Longest running processor for workqueue " + name,
+	})
+}
+
+func (prometheusMetricsProvider) NewRetriesMetric(name string) workqueue.CounterMetric {
+	return prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "workqueue",
+		Name:      name + "_retries_total",
+		Help:      "Total number of retries handled by workqueue " + name,
+	})
+}
@@ -0,0 +1,280 @@
+/*
+Copyright 2014 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/controller"
+	quota "k8s.io/kubernetes/pkg/quota/v1"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// ReplenishmentFunc is invoked by a monitor to notify that an account quota should be recalculated
+// because an object it tracks changed in namespace. object is the object's state after the change
+// (nil on delete) and oldObject is its state before the change (nil on create); passing both lets the
+// caller compute a precise usage delta via quota.Add/quota.Subtract instead of re-listing the namespace.
+type ReplenishmentFunc func(ctx context.Context, groupResource schema.GroupResource, namespace string, object, oldObject runtime.Object)
+
+// event is queued on resourceChanges whenever a monitored resource changes so that replenishment
+// can happen off of the informer's event handler goroutine.
+type event struct {
+	namespace string
+	gvr       schema.GroupVersionResource
+	object    runtime.Object
+	oldObject runtime.Object
+}
+
+// monitor tracks a single dynamic informer started for one GroupVersionResource.
+type monitor struct {
+	informer cache.SharedIndexInformer
+	stopCh   chan struct{}
+}
+
+type monitors map[schema.GroupVersionResource]*monitor
+
+// QuotaMonitor knows how to start/stop a dynamic informer for every namespaced resource tracked by
+// quota, and to enqueue a replenishment whenever an object belonging to one of those resources changes.
+// It replaces registering a typed informer per resource up front with informers created on demand from
+// discovery, so arbitrary resources - including CRDs - can be tracked without code changes.
+type QuotaMonitor struct {
+	// manager has access to the shared cache and client used elsewhere in the controller
+	manager manager.Manager
+	// informerFactory constructs and caches dynamic informers per GroupVersionResource
+	informerFactory dynamicinformer.DynamicSharedInformerFactory
+	// resources that should never be monitored for quota purposes
+	ignoredResources map[schema.GroupResource]struct{}
+	// registry knows how to calculate usage for a group resource; only resources it has an evaluator
+	// for are worth tracking
+	registry quota.Registry
+	// controls the resync period of each monitor's informer
+	resyncPeriod controller.ResyncPeriodFunc
+	// replenishmentFunc is called when a monitored resource changes
+	replenishmentFunc ReplenishmentFunc
+
+	// resourceChanges holds events from running monitors to be dispatched to replenishmentFunc
+	resourceChanges workqueue.RateLimitingInterface
+
+	monitorLock sync.Mutex
+	monitors    monitors
+	running     bool
+	stopCh      <-chan struct{}
+}
+
+// controllerFor registers replenishment handlers on the dynamic informer for gvr and wraps it in a
+// new monitor, starting the informer right away if the QuotaMonitor is already running. It does not
+// look for an existing monitor for gvr; every call adds another set of handlers.
+func (qm *QuotaMonitor) controllerFor(gvr schema.GroupVersionResource) (*monitor, error) {
+	informer := qm.informerFactory.ForResource(gvr).Informer()
+
+	handlers := cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			qm.enqueueEvent(gvr, obj, nil)
+		},
+		UpdateFunc: func(old, cur interface{}) {
+			qm.enqueueEvent(gvr, cur, old)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if deletedFinalStateUnknown, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = deletedFinalStateUnknown.Obj
+			}
+			qm.enqueueEvent(gvr, nil, obj)
+		},
+	}
+	informer.AddEventHandlerWithResyncPeriod(handlers, qm.resyncPeriod())
+
+	m := &monitor{
+		informer: informer,
+		stopCh:   make(chan struct{}),
+	}
+
+	// if the monitor set is already running, this one needs to be started immediately
+	if qm.running {
+		go informer.Run(m.stopCh)
+	}
+
+	return m, nil
+}
+
+// enqueueEvent queues a replenishment event for gvr. obj is the object's state after the change and
+// oldObj its state before the change; either may be nil (but not both) for a create/delete.
+func (qm *QuotaMonitor) enqueueEvent(gvr schema.GroupVersionResource, obj, oldObj interface{}) {
+	var object, oldObject runtime.Object
+	var namespace string
+
+	if obj != nil {
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			utilruntime.HandleError(fmt.Errorf("expected *unstructured.Unstructured, got %T for %v", obj, gvr))
+			return
+		}
+		object = u
+		namespace = u.GetNamespace()
+	}
+	if oldObj != nil {
+		u, ok := oldObj.(*unstructured.Unstructured)
+		if !ok {
+			utilruntime.HandleError(fmt.Errorf("expected *unstructured.Unstructured, got %T for %v", oldObj, gvr))
+			return
+		}
+		oldObject = u
+		if namespace == "" {
+			namespace = u.GetNamespace()
+		}
+	}
+
+	qm.resourceChanges.Add(&event{
+		namespace: namespace,
+		gvr:       gvr,
+		object:    object,
+		oldObject: oldObject,
+	})
+}
+
+// SyncMonitors ensures that a monitor is running for each resource in the provided set, and that
+// monitors for resources no longer present are stopped and removed. It is safe to call repeatedly;
+// only the diff between the currently running monitors and resources is acted on.
+func (qm *QuotaMonitor) SyncMonitors(resources map[schema.GroupVersionResource]struct{}) error {
+	qm.monitorLock.Lock()
+	defer qm.monitorLock.Unlock()
+
+	var errs []error
+
+	toRemove := monitors{}
+	for gvr, m := range qm.monitors {
+		toRemove[gvr] = m
+	}
+	if qm.monitors == nil {
+		qm.monitors = monitors{}
+	}
+
+	toCreate := []schema.GroupVersionResource{}
+	for gvr := range resources {
+		groupResource := schema.GroupResource{Group: gvr.Group, Resource: gvr.Resource}
+		if qm.ignoredResources != nil {
+			if _, ignored := qm.ignoredResources[groupResource]; ignored {
+				continue
+			}
+		}
+		if qm.registry != nil && qm.registry.Get(groupResource) == nil {
+			// nothing can ever reference this resource from a quota, no point watching it
+			continue
+		}
+		if _, exists := qm.monitors[gvr]; exists {
+			delete(toRemove, gvr)
+			continue
+		}
+		toCreate = append(toCreate, gvr)
+	}
+
+	for gvr, m := range toRemove {
+		delete(qm.monitors, gvr)
+		close(m.stopCh)
+	}
+
+	for _, gvr := range toCreate {
+		m, err := qm.controllerFor(gvr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to start monitor for resource %v: %v", gvr, err))
+			continue
+		}
+		qm.monitors[gvr] = m
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// Run starts all currently configured monitors and processes resource change events until ctx is
+// done.
+func (qm *QuotaMonitor) Run(ctx context.Context) {
+	logger := klog.FromContext(ctx)
+	logger.Info("Starting quota monitor")
+	defer logger.Info("Shutting down quota monitor")
+
+	stopCh := ctx.Done()
+
+	qm.monitorLock.Lock()
+	qm.stopCh = stopCh
+	qm.running = true
+	for _, m := range qm.monitors {
+		go m.informer.Run(m.stopCh)
+	}
+	qm.monitorLock.Unlock()
+
+	go qm.runProcessResourceChanges(ctx)
+
+	<-stopCh
+
+	qm.monitorLock.Lock()
+	defer qm.monitorLock.Unlock()
+	for _, m := range qm.monitors {
+		close(m.stopCh)
+	}
+	qm.resourceChanges.ShutDown()
+}
+
+func (qm *QuotaMonitor) runProcessResourceChanges(ctx context.Context) {
+	for {
+		item, quit := qm.resourceChanges.Get()
+		if quit {
+			return
+		}
+		func() {
+			defer qm.resourceChanges.Done(item)
+			evt := item.(*event)
+			groupResource := schema.GroupResource{Group: evt.gvr.Group, Resource: evt.gvr.Resource}
+			qm.replenishmentFunc(ctx, groupResource, evt.namespace, evt.object, evt.oldObject)
+			qm.resourceChanges.Forget(item)
+		}()
+
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+	}
+}
+
+// IsSynced returns true once every currently configured monitor's informer cache has synced. It is
+// used as a cache.InformerSynced so callers can wait for a discovery-driven resync to settle.
+func (qm *QuotaMonitor) IsSynced() bool {
+	qm.monitorLock.Lock()
+	defer qm.monitorLock.Unlock()
+
+	if len(qm.monitors) == 0 {
+		return true
+	}
+
+	for gvr, m := range qm.monitors {
+		if !m.informer.HasSynced() {
+			klog.V(4).Infof("quota monitor not yet synced for %v", gvr)
+			return false
+		}
+	}
+	return true
+}
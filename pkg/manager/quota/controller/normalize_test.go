@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestAccountQuotaControllerNormalizeResourceNames(t *testing.T) {
+	rq := &AccountQuotaController{
+		resourceAliases: map[v1.ResourceName]v1.ResourceName{
+			"openshift.io/imagestreams": "count/imagestreams.image.openshift.io",
+		},
+	}
+
+	in := v1.ResourceList{
+		"openshift.io/imagestreams": resource.MustParse("5"),
+		"pods":                      resource.MustParse("10"),
+	}
+
+	got := rq.normalizeResourceNames(in)
+
+	if _, ok := got["openshift.io/imagestreams"]; ok {
+		t.Errorf("expected the alias key to be rewritten away, got %v", got)
+	}
+	if q, ok := got["count/imagestreams.image.openshift.io"]; !ok || q.String() != "5" {
+		t.Errorf("expected the canonical name to carry the alias's quantity, got %v", got)
+	}
+	if q, ok := got["pods"]; !ok || q.String() != "10" {
+		t.Errorf("expected an unaliased resource to pass through unchanged, got %v", got)
+	}
+}
+
+func TestAccountQuotaControllerNormalizeResourceNamesNoAliases(t *testing.T) {
+	rq := &AccountQuotaController{}
+	in := v1.ResourceList{"pods": resource.MustParse("1")}
+
+	got := rq.normalizeResourceNames(in)
+
+	if q, ok := got["pods"]; !ok || q.String() != "1" {
+		t.Errorf("expected resources to pass through unchanged when no aliases are configured, got %v", got)
+	}
+}
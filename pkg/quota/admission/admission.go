@@ -0,0 +1,238 @@
+package admission
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	configv1alpha1 "github.com/kiosk-sh/kiosk/pkg/apis/config/v1alpha1"
+	"github.com/kiosk-sh/kiosk/pkg/constants"
+	"github.com/kiosk-sh/kiosk/pkg/quota/tracker"
+	"github.com/kiosk-sh/kiosk/pkg/util"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	quota "k8s.io/kubernetes/pkg/quota/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// WebhookPath is the path the account quota admission webhook is served under.
+const WebhookPath = "/validate-accountquota"
+
+// AccountQuotaAdmission enforces AccountQuota.Spec.Quota.Hard synchronously, before an object is
+// persisted, by evaluating it against the same quota.Registry the AccountQuotaController uses to
+// reconcile usage after the fact. This closes the window where a tenant could briefly exceed its
+// hard limits between admission and the controller's next reconcile.
+type AccountQuotaAdmission struct {
+	client   client.Client
+	registry quota.Registry
+	decoder  *admission.Decoder
+	// resourceAliases maps a user-facing resource name to the canonical name the registry's
+	// evaluators track internally, matching AccountQuotaController's ResourceAliases so admission
+	// enforces the same limits the controller reconciles against.
+	resourceAliases map[v1.ResourceName]v1.ResourceName
+	// pendingUsage marks which objects' usage was already applied to an AccountQuota's status here
+	// at admission time, shared with AccountQuotaController so its informer-driven replenishment for
+	// the same object doesn't add that usage a second time.
+	pendingUsage *tracker.PendingUsage
+}
+
+// NewAccountQuotaAdmission creates an admission plugin that enforces quota for every resource
+// registry has an evaluator for. resourceAliases should match the AccountQuotaController's, so a
+// hard limit expressed in terms of an alias is enforced under the same canonical name. pendingUsage
+// should be the same tracker passed to AccountQuotaControllerOptions.PendingUsage.
+func NewAccountQuotaAdmission(client client.Client, registry quota.Registry, resourceAliases map[v1.ResourceName]v1.ResourceName, pendingUsage *tracker.PendingUsage) *AccountQuotaAdmission {
+	return &AccountQuotaAdmission{
+		client:          client,
+		registry:        registry,
+		resourceAliases: resourceAliases,
+		pendingUsage:    pendingUsage,
+	}
+}
+
+// normalizeResourceNames rewrites any key in resources that has a configured alias to its canonical
+// form, leaving everything else untouched. Mirrors AccountQuotaController.normalizeResourceNames so
+// admission and reconcile agree on what a hard limit refers to.
+func (a *AccountQuotaAdmission) normalizeResourceNames(resources v1.ResourceList) v1.ResourceList {
+	if len(a.resourceAliases) == 0 {
+		return resources
+	}
+
+	normalized := make(v1.ResourceList, len(resources))
+	for name, quantity := range resources {
+		if canonical, ok := a.resourceAliases[name]; ok {
+			name = canonical
+		}
+		normalized[name] = quantity
+	}
+	return normalized
+}
+
+// SetupWebhookWithManager registers the admission plugin as a validating webhook on mgr's webhook
+// server, served alongside the rest of the kiosk apiserver.
+func (a *AccountQuotaAdmission) SetupWebhookWithManager(mgr manager.Manager) error {
+	mgr.GetWebhookServer().Register(WebhookPath, &webhook.Admission{Handler: a})
+	return nil
+}
+
+// InjectDecoder is called by the controller-runtime manager to give the plugin a decoder for the
+// incoming admission.Request.
+func (a *AccountQuotaAdmission) InjectDecoder(d *admission.Decoder) error {
+	a.decoder = d
+	return nil
+}
+
+// quotaUpdate is a validated, not-yet-committed usage change for a single AccountQuota, produced by
+// Handle's validation pass and applied by its commit pass only once every matched quota has passed.
+type quotaUpdate struct {
+	accountQuota *configv1alpha1.AccountQuota
+	delta        v1.ResourceList
+}
+
+// Handle evaluates the incoming create/update against every AccountQuota whose Spec.Account maps to
+// the request's namespace, and rejects it if, with its usage added to the quota's current usage,
+// any tracked resource would be over its hard limit. An update that does not increase usage is
+// rejected too when the account's usage is already over the limit.
+func (a *AccountQuotaAdmission) Handle(ctx context.Context, req admission.Request) admission.Response {
+	groupResource := schema.GroupResource{Group: req.Resource.Group, Resource: req.Resource.Resource}
+	evaluator := a.registry.Get(groupResource)
+	if evaluator == nil {
+		// nothing tracks this resource, nothing to enforce
+		return admission.Allowed("resource is not quota-tracked")
+	}
+
+	object := &unstructured.Unstructured{}
+	if err := a.decoder.Decode(req, object); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	newUsage, err := evaluator.Usage(object)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, fmt.Errorf("computing usage: %v", err))
+	}
+
+	// An update already contributed its usage at the original create; only the delta between the old
+	// and new object's usage matters here; a create contributes its full usage.
+	delta := newUsage
+	isCreate := req.Operation == admissionv1.Create
+	if !isCreate {
+		oldObject := &unstructured.Unstructured{}
+		if err := a.decoder.DecodeRaw(req.OldObject, oldObject); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		oldUsage, err := evaluator.Usage(oldObject)
+		if err != nil {
+			return admission.Errored(http.StatusInternalServerError, fmt.Errorf("computing usage: %v", err))
+		}
+		delta = quota.Subtract(newUsage, oldUsage)
+	}
+
+	namespace := &v1.Namespace{}
+	if err := a.client.Get(ctx, types.NamespacedName{Name: req.Namespace}, namespace); err != nil {
+		return admission.Errored(http.StatusInternalServerError, fmt.Errorf("looking up namespace %s: %v", req.Namespace, err))
+	}
+	account := util.GetAccountFromNamespace(namespace)
+	if account == "" {
+		// namespace isn't part of an account, nothing to enforce
+		return admission.Allowed("namespace is not part of an account")
+	}
+
+	accountQuotaList := &configv1alpha1.AccountQuotaList{}
+	if err := a.client.List(ctx, accountQuotaList, client.MatchingField(constants.IndexByAccount, account)); err != nil {
+		return admission.Errored(http.StatusInternalServerError, fmt.Errorf("listing account quotas for account %s: %v", account, err))
+	}
+
+	// Validate against every matched quota before committing anything: if a later quota in the list
+	// were to deny the request after earlier quotas already had recordUsage called, those earlier
+	// quotas would be left over-counted for an object that was never actually persisted.
+	updates := make([]quotaUpdate, 0, len(accountQuotaList.Items))
+	for i := range accountQuotaList.Items {
+		accountQuota := &accountQuotaList.Items[i]
+		// Enforce against the (alias-normalized) spec, not Status.Total.Hard: status only reflects
+		// spec once the controller's next reconcile runs, so a brand-new quota or one whose limits
+		// were just tightened would otherwise be enforced against stale or absent limits.
+		hardLimits := a.normalizeResourceNames(accountQuota.Spec.Quota.Hard)
+		hardResources := quota.ResourceNames(hardLimits)
+		maskedDelta := quota.Mask(delta, hardResources)
+		if len(maskedDelta) == 0 {
+			// this quota doesn't constrain any resource the incoming object contributes to
+			continue
+		}
+
+		if exceeded := exceedsHardLimits(accountQuota.Status.Total.Used, maskedDelta, hardLimits); len(exceeded) > 0 {
+			return admission.Denied(fmt.Sprintf("exceeded quota %s: requested %v, used %v, limited %v",
+				accountQuota.Name, maskedDelta, accountQuota.Status.Total.Used, quota.Mask(hardLimits, exceeded)))
+		}
+
+		updates = append(updates, quotaUpdate{accountQuota: accountQuota, delta: maskedDelta})
+	}
+
+	for _, u := range updates {
+		if err := a.recordUsage(ctx, u.accountQuota.Name, req.Namespace, u.delta); err != nil {
+			return admission.Errored(http.StatusInternalServerError, fmt.Errorf("recording usage against quota %s: %v", u.accountQuota.Name, err))
+		}
+	}
+
+	if isCreate && a.pendingUsage != nil {
+		// lets the controller's informer-driven replenishment recognize this object's usage as
+		// already applied once it observes the object, instead of adding it a second time. Only
+		// create events are ever matched against this by syncNamespaceDelta, so only mark it here.
+		a.pendingUsage.MarkApplied(object.GetUID())
+	}
+
+	return admission.Allowed("")
+}
+
+// exceedsHardLimits returns the names of the resources in hard that would be exceeded if delta were
+// added to used.
+func exceedsHardLimits(used, delta, hard v1.ResourceList) []v1.ResourceName {
+	newUsed := quota.Mask(quota.Add(quota.Add(v1.ResourceList{}, used), delta), quota.ResourceNames(hard))
+	return quota.IsNegative(quota.Subtract(hard, newUsed))
+}
+
+// recordUsage atomically adds delta to accountQuota's Status.Total.Used and to the Status.Namespaces
+// entry for namespace (creating it if this is the first usage recorded for it), retrying on write
+// conflicts so that concurrent admissions across namespaces of the same account converge on the
+// correct total. The controller's periodic full sync remains the corrective, self-healing background
+// job; this is only an optimistic best-effort bump so the next admission sees up-to-date usage, and
+// so the controller's targeted replenishment finds a namespace entry to patch instead of falling back
+// to a full recalculation on this namespace's first object.
+func (a *AccountQuotaAdmission) recordUsage(ctx context.Context, accountQuotaName, namespace string, delta v1.ResourceList) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		accountQuota := &configv1alpha1.AccountQuota{}
+		if err := a.client.Get(ctx, types.NamespacedName{Name: accountQuotaName}, accountQuota); err != nil {
+			return err
+		}
+
+		hardResources := quota.ResourceNames(a.normalizeResourceNames(accountQuota.Spec.Quota.Hard))
+
+		used := quota.Add(v1.ResourceList{}, accountQuota.Status.Total.Used)
+		used = quota.Mask(quota.Add(used, delta), hardResources)
+		accountQuota.Status.Total.Used = used
+
+		nsIndex := -1
+		for i := range accountQuota.Status.Namespaces {
+			if accountQuota.Status.Namespaces[i].Namespace == namespace {
+				nsIndex = i
+				break
+			}
+		}
+		if nsIndex == -1 {
+			accountQuota.Status.Namespaces = append(accountQuota.Status.Namespaces, configv1alpha1.AccountQuotaStatusByNamespace{Namespace: namespace})
+			nsIndex = len(accountQuota.Status.Namespaces) - 1
+		}
+
+		nsUsed := quota.Add(v1.ResourceList{}, accountQuota.Status.Namespaces[nsIndex].Status.Used)
+		nsUsed = quota.Mask(quota.Add(nsUsed, delta), hardResources)
+		accountQuota.Status.Namespaces[nsIndex].Status.Used = nsUsed
+
+		return a.client.Status().Update(ctx, accountQuota)
+	})
+}
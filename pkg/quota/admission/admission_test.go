@@ -0,0 +1,97 @@
+package admission
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestExceedsHardLimits(t *testing.T) {
+	tests := []struct {
+		name  string
+		used  v1.ResourceList
+		delta v1.ResourceList
+		hard  v1.ResourceList
+		want  []v1.ResourceName
+	}{
+		{
+			name:  "delta fits within the remaining hard limit",
+			used:  v1.ResourceList{"pods": resource.MustParse("3")},
+			delta: v1.ResourceList{"pods": resource.MustParse("1")},
+			hard:  v1.ResourceList{"pods": resource.MustParse("5")},
+			want:  nil,
+		},
+		{
+			name:  "delta exactly exhausts the hard limit",
+			used:  v1.ResourceList{"pods": resource.MustParse("4")},
+			delta: v1.ResourceList{"pods": resource.MustParse("1")},
+			hard:  v1.ResourceList{"pods": resource.MustParse("5")},
+			want:  nil,
+		},
+		{
+			name:  "delta pushes usage over the hard limit",
+			used:  v1.ResourceList{"pods": resource.MustParse("4")},
+			delta: v1.ResourceList{"pods": resource.MustParse("2")},
+			hard:  v1.ResourceList{"pods": resource.MustParse("5")},
+			want:  []v1.ResourceName{"pods"},
+		},
+		{
+			name:  "a negative delta (update shrinking usage) at the hard limit does not exceed",
+			used:  v1.ResourceList{"pods": resource.MustParse("5")},
+			delta: v1.ResourceList{"pods": resource.MustParse("-2")},
+			hard:  v1.ResourceList{"pods": resource.MustParse("5")},
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := exceedsHardLimits(tt.used, tt.delta, tt.hard)
+			if len(got) != len(tt.want) {
+				t.Fatalf("exceedsHardLimits() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("exceedsHardLimits() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestAccountQuotaAdmissionNormalizeResourceNames(t *testing.T) {
+	a := &AccountQuotaAdmission{
+		resourceAliases: map[v1.ResourceName]v1.ResourceName{
+			"openshift.io/imagestreams": "count/imagestreams.image.openshift.io",
+		},
+	}
+
+	in := v1.ResourceList{
+		"openshift.io/imagestreams": resource.MustParse("5"),
+		"pods":                      resource.MustParse("10"),
+	}
+
+	got := a.normalizeResourceNames(in)
+
+	if _, ok := got["openshift.io/imagestreams"]; ok {
+		t.Errorf("expected the alias key to be rewritten away, got %v", got)
+	}
+	if q, ok := got["count/imagestreams.image.openshift.io"]; !ok || q.String() != "5" {
+		t.Errorf("expected the canonical name to carry the alias's quantity, got %v", got)
+	}
+	if q, ok := got["pods"]; !ok || q.String() != "10" {
+		t.Errorf("expected an unaliased resource to pass through unchanged, got %v", got)
+	}
+}
+
+func TestAccountQuotaAdmissionNormalizeResourceNamesNoAliases(t *testing.T) {
+	a := &AccountQuotaAdmission{}
+	in := v1.ResourceList{"pods": resource.MustParse("1")}
+
+	got := a.normalizeResourceNames(in)
+
+	if q, ok := got["pods"]; !ok || q.String() != "1" {
+		t.Errorf("expected resources to pass through unchanged when no aliases are configured, got %v", got)
+	}
+}
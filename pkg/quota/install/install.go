@@ -0,0 +1,39 @@
+// Package install builds the quota.Registry used by AccountQuotaController, the same way
+// k8s.io/kubernetes/pkg/quota/v1/install builds the registry for the upstream resource-quota
+// controller. Keeping registry assembly here - rather than inline in the controller constructor -
+// lets downstream consumers of kiosk plug in evaluators for their own CRDs (storage claims per
+// storage class, GPU counters, per-account object budgets, ...) without forking the controller,
+// mirroring how cluster-policy-controller plugs OpenShift-specific quota monitors into upstream.
+package install
+
+import (
+	"k8s.io/kubernetes/pkg/quota/v1/generic"
+
+	quota "k8s.io/kubernetes/pkg/quota/v1"
+)
+
+// QuotaConfiguration contributes a set of evaluators to the registry built by NewRegistry.
+type QuotaConfiguration interface {
+	// Evaluators returns the evaluators this configuration knows how to build.
+	Evaluators() []quota.Evaluator
+}
+
+// NewRegistry builds a quota.Registry out of the evaluators contributed by every provider, in order.
+// A resource evaluated by more than one provider keeps the evaluator from the first provider that
+// contributed it.
+func NewRegistry(providers ...QuotaConfiguration) quota.Registry {
+	evaluators := []quota.Evaluator{}
+	seen := map[string]struct{}{}
+	for _, provider := range providers {
+		for _, evaluator := range provider.Evaluators() {
+			gr := evaluator.GroupResource()
+			key := gr.String()
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			evaluators = append(evaluators, evaluator)
+		}
+	}
+	return generic.NewRegistry(evaluators)
+}
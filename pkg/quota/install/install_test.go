@@ -0,0 +1,68 @@
+package install
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	quota "k8s.io/kubernetes/pkg/quota/v1"
+	"k8s.io/kubernetes/pkg/quota/v1/generic"
+)
+
+// fakeQuotaConfiguration is a minimal QuotaConfiguration backed by a fixed set of evaluators, used to
+// exercise NewRegistry's merge behavior without standing up any informer machinery.
+type fakeQuotaConfiguration struct {
+	evaluators []quota.Evaluator
+}
+
+func (f fakeQuotaConfiguration) Evaluators() []quota.Evaluator {
+	return f.evaluators
+}
+
+func noopListFunc(namespace string) ([]runtime.Object, error) {
+	return nil, nil
+}
+
+func TestNewRegistryMergesProviders(t *testing.T) {
+	pods := generic.NewObjectCountEvaluator(schema.GroupResource{Resource: "pods"}, noopListFunc, "count/pods")
+	deployments := generic.NewObjectCountEvaluator(schema.GroupResource{Group: "apps", Resource: "deployments"}, noopListFunc, "count/deployments.apps")
+
+	registry := NewRegistry(
+		fakeQuotaConfiguration{evaluators: []quota.Evaluator{pods}},
+		fakeQuotaConfiguration{evaluators: []quota.Evaluator{deployments}},
+	)
+
+	if got := len(registry.List()); got != 2 {
+		t.Fatalf("List() returned %d evaluators, want 2", got)
+	}
+	if registry.Get(schema.GroupResource{Resource: "pods"}) == nil {
+		t.Error("expected registry to have an evaluator for pods")
+	}
+	if registry.Get(schema.GroupResource{Group: "apps", Resource: "deployments"}) == nil {
+		t.Error("expected registry to have an evaluator for deployments.apps")
+	}
+}
+
+func TestNewRegistryFirstProviderWinsOnDuplicate(t *testing.T) {
+	first := generic.NewObjectCountEvaluator(schema.GroupResource{Resource: "configmaps"}, noopListFunc, "count/configmaps")
+	second := generic.NewObjectCountEvaluator(schema.GroupResource{Resource: "configmaps"}, noopListFunc, "count/configmaps.duplicate")
+
+	registry := NewRegistry(
+		fakeQuotaConfiguration{evaluators: []quota.Evaluator{first}},
+		fakeQuotaConfiguration{evaluators: []quota.Evaluator{second}},
+	)
+
+	if got := len(registry.List()); got != 1 {
+		t.Fatalf("List() returned %d evaluators, want 1 (duplicate should be dropped)", got)
+	}
+
+	kept := registry.Get(schema.GroupResource{Resource: "configmaps"})
+	if kept == nil {
+		t.Fatal("expected registry to have an evaluator for configmaps")
+	}
+	// only the second provider's evaluator matches its extra resource name
+	if got := kept.MatchingResources([]v1.ResourceName{"count/configmaps.duplicate"}); len(got) != 0 {
+		t.Errorf("expected the first provider's evaluator to be kept, got one matching %v", got)
+	}
+}
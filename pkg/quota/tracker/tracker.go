@@ -0,0 +1,41 @@
+// Package tracker lets the account quota admission webhook and AccountQuotaController agree on
+// which object usages have already been applied to an AccountQuota's status at admission time, so
+// that the informer-driven replenishment for the same object doesn't add that usage a second time.
+package tracker
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// PendingUsage records the UIDs of objects whose usage was applied to an AccountQuota's status by
+// the admission webhook, but not yet confirmed by the controller observing the object itself.
+type PendingUsage struct {
+	mu      sync.Mutex
+	pending map[types.UID]struct{}
+}
+
+// New returns an empty PendingUsage tracker.
+func New() *PendingUsage {
+	return &PendingUsage{pending: map[types.UID]struct{}{}}
+}
+
+// MarkApplied records that uid's usage has already been applied to an AccountQuota's status.
+func (p *PendingUsage) MarkApplied(uid types.UID) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pending[uid] = struct{}{}
+}
+
+// TakeIfApplied reports whether uid's usage was already applied by admission. If so, the record is
+// cleared so a later event for the same UID (e.g. a subsequent update) isn't matched again.
+func (p *PendingUsage) TakeIfApplied(uid types.UID) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, ok := p.pending[uid]
+	if ok {
+		delete(p.pending, uid)
+	}
+	return ok
+}